@@ -0,0 +1,47 @@
+package container
+
+import "github.com/moby/moby/api/types/blkiodev"
+
+// Resources contains container's resources (cgroups config, ulimits...)
+type Resources struct {
+	// Applicable to all platforms
+	CPUShares int64 `json:"CpuShares"` // CPU shares (relative weight vs. other containers)
+	Memory    int64 // Memory limit (in bytes)
+	NanoCPUs  int64 `json:"NanoCpus"` // CPU quota in units of 10<sup>-9</sup> CPUs.
+
+	// Applicable to UNIX platforms
+	CgroupParent         string // Parent cgroup.
+	BlkioWeight          uint16 // Block IO weight (relative weight vs. other containers)
+	BlkioWeightDevice    []*blkiodev.WeightDevice
+	BlkioDeviceReadBps   []*blkiodev.ThrottleDevice
+	BlkioDeviceWriteBps  []*blkiodev.ThrottleDevice
+	BlkioDeviceReadIOps  []*blkiodev.ThrottleDevice
+	BlkioDeviceWriteIOps []*blkiodev.ThrottleDevice
+	CPUPeriod            int64  `json:"CpuPeriod"` // CPU CFS (Completely Fair Scheduler) period
+	CPUQuota             int64  `json:"CpuQuota"`  // CPU CFS (Completely Fair Scheduler) quota
+	CPURealtimePeriod    int64  `json:"CpuRealtimePeriod"`
+	CPURealtimeRuntime   int64  `json:"CpuRealtimeRuntime"`
+	CpusetCpus           string // CpusetCpus 0-2, 0,1
+	CpusetMems           string // CpusetMems 0-2, 0,1
+	Devices              []string
+	DeviceCgroupRules    []string
+	DeviceRequests       []string
+	KernelMemory         int64 // Kernel memory limit (in bytes), Deprecated: kernel 5.4 deprecated kmem.limit_in_bytes.
+	KernelMemoryTCP      int64 // Hard limit for kernel TCP buffer memory (in bytes)
+	MemoryReservation    int64 // Memory soft limit (in bytes)
+	// MemoryHigh is the cgroup v2 memory.high watermark (in bytes): a soft
+	// cap enforced before the hard Memory limit is hit, reclaiming memory
+	// under pressure rather than invoking the OOM killer. It has no v1
+	// equivalent, so it is ignored on cgroup v1 hosts.
+	MemoryHigh       int64
+	MemorySwap       int64  // Total memory usage (memory + swap); set `-1` to enable unlimited swap
+	MemorySwappiness *int64 // Tuning container memory swappiness behaviour
+	OomKillDisable   *bool  // Whether to disable OOM Killer or not
+	PidsLimit        *int64 // Setting PIDs limit for a container; Set `0` or `-1` for unlimited, or `null` to not change.
+
+	// CPU Count field is actually CPU quota in Windows.
+	CPUCount           int64 `json:"CpuCount"`
+	CPUPercent         int64 `json:"CpuPercent"`
+	IOMaximumIOps      uint64
+	IOMaximumBandwidth uint64
+}
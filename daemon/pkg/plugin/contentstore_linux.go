@@ -0,0 +1,15 @@
+package plugin
+
+import "github.com/containerd/containerd/v2/core/content"
+
+// contentStore returns the content store plugin pulls fetch into: pm's
+// sharedContentStore (normally the daemon's main containerd content store)
+// when one is configured, so plugin layers dedupe against image layers and
+// other plugins' layers and survive a daemon restart with resumable writes;
+// the plugin-local blobStore otherwise.
+func (pm *Manager) contentStore() content.Store {
+	if pm.sharedContentStore != nil {
+		return pm.sharedContentStore
+	}
+	return pm.blobStore
+}
@@ -12,16 +12,16 @@ import (
 	"github.com/containerd/containerd/v2/core/remotes/docker"
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/containerd/log"
+	"github.com/containerd/platforms"
 	"github.com/distribution/reference"
-	"github.com/moby/go-archive/chrootarchive"
 	"github.com/moby/moby/api/pkg/progress"
 	"github.com/moby/moby/api/types/registry"
 	progressutils "github.com/moby/moby/v2/daemon/internal/distribution/utils"
 	"github.com/moby/moby/v2/daemon/internal/stringid"
-	"github.com/moby/moby/v2/pkg/ioutils"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 )
 
 const mediaTypePluginConfig = "application/vnd.docker.plugin.v1+json"
@@ -57,9 +57,19 @@ func setupProgressOutput(outStream io.Writer, cancel func()) (progress.Output, f
 	return out, f
 }
 
-// fetch the content related to the passed in reference into the blob store and appends the provided c8dimages.Handlers
-// There is no need to use remotes.FetchHandler since it already gets set
-func (pm *Manager) fetch(ctx context.Context, ref reference.Named, auth *registry.AuthConfig, out progress.Output, metaHeader http.Header, handlers ...c8dimages.Handler) error {
+// fetch resolves ref, downloads its layers and applies them to dir, recording
+// digests of everything it touches in md.
+//
+// If opts.Platform is nil, the platform of the host running the daemon is used. When the
+// resolved reference is a manifest list or OCI index, only the manifest matching the
+// platform is walked; fetch returns an error naming the available platforms if none match.
+//
+// Layers are downloaded concurrently, bounded by opts.MaxConcurrentDownloads (default
+// defaultMaxConcurrentDownloads), while a single goroutine applies them to dir strictly in
+// manifest order as each one finishes downloading, so "Downloading" and "Extracting"
+// progress are reported independently per layer without rootfs correctness depending on
+// download order.
+func (pm *Manager) fetch(ctx context.Context, ref reference.Named, auth *registry.AuthConfig, out progress.Output, metaHeader http.Header, opts PullOptions, dir string, md *fetchMeta) error {
 	// We need to make sure we have a domain on the reference
 	withDomain, err := reference.ParseNormalizedNamed(ref.String())
 	if err != nil {
@@ -107,45 +117,93 @@ func (pm *Manager) fetch(ctx context.Context, ref reference.Named, auth *registr
 		return errors.Wrap(err, "error creating plugin image fetcher")
 	}
 
-	fp := withFetchProgress(pm.blobStore, out, ref)
-	handlers = append([]c8dimages.Handler{fp, remotes.FetchHandler(pm.blobStore, fetcher)}, handlers...)
-	return c8dimages.Dispatch(ctx, c8dimages.Handlers(handlers...), nil, desc)
-}
-
-// applyLayer makes an c8dimages.HandlerFunc which applies a fetched image rootfs layer to a directory.
-//
-// TODO(@cpuguy83) This gets run sequentially after layer pull (makes sense), however
-// if there are multiple layers to fetch we may end up extracting layers in the wrong
-// order.
-func applyLayer(cs content.Store, dir string, out progress.Output) c8dimages.HandlerFunc {
-	return func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
-		switch desc.MediaType {
-		case
-			ocispec.MediaTypeImageLayer,
-			c8dimages.MediaTypeDockerSchema2Layer,
-			ocispec.MediaTypeImageLayerGzip,
-			c8dimages.MediaTypeDockerSchema2LayerGzip:
-		default:
-			return nil, nil
+	p := platforms.DefaultSpec()
+	if opts.Platform != nil {
+		p = *opts.Platform
+	}
+	matcher := platforms.OnlyStrict(p)
+
+	fp := withFetchProgress(pm.contentStore(), out, ref)
+	fetchHandler := remotes.FetchHandler(pm.contentStore(), fetcher)
+
+	// Resolve down to a single manifest before fetching anything layer-sized: we need the
+	// ordered layer list up front so the extractor below can apply layers in manifest order
+	// regardless of the order they finish downloading in.
+	manifestDesc := desc
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, c8dimages.MediaTypeDockerSchema2ManifestList:
+		if err := c8dimages.Dispatch(ctx, c8dimages.Handlers(fp, fetchHandler), nil, desc); err != nil {
+			return errors.Wrap(err, "error fetching plugin index")
 		}
-
-		ra, err := cs.ReaderAt(ctx, desc)
+		selected, err := platformManifestHandler(pm.contentStore(), matcher)(ctx, desc)
 		if err != nil {
-			return nil, errors.Wrapf(err, "error getting content from content store for digest %s", desc.Digest)
+			return err
 		}
+		manifestDesc = selected[0]
+	}
 
-		id := stringid.TruncateID(desc.Digest.String())
+	if err := pm.verifySignature(ctx, ref, manifestDesc, opts); err != nil {
+		return errors.Wrap(err, "plugin signature verification failed")
+	}
 
-		rc := ioutils.NewReadCloserWrapper(content.NewReader(ra), ra.Close)
-		pr := progress.NewProgressReader(rc, out, desc.Size, id, "Extracting")
-		defer pr.Close()
+	topHandlers := c8dimages.Handlers(fp, fetchHandler, storeFetchMetadata(md))
+	if err := c8dimages.Dispatch(ctx, topHandlers, nil, manifestDesc); err != nil {
+		return errors.Wrap(err, "error fetching plugin manifest")
+	}
 
-		if _, err := chrootarchive.ApplyLayer(dir, pr); err != nil {
-			return nil, errors.Wrapf(err, "error applying layer for digest %s", desc.Digest)
+	manifest, err := readManifest(ctx, pm.contentStore(), manifestDesc)
+	if err != nil {
+		return err
+	}
+
+	// Persist enough of the resolve to rebuild this pull and resume it with Resume if the
+	// daemon restarts before the layer downloads below finish.
+	if err := savePullState(dir, newPullState(withDomain, manifestDesc, manifest)); err != nil {
+		return errors.Wrap(err, "error persisting plugin pull state")
+	}
+
+	maxConcurrent := opts.MaxConcurrentDownloads
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultMaxConcurrentDownloads
+	}
+
+	lp := newLayerPipeline(manifest.Layers)
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(maxConcurrent)
+
+	fetchOne := func(d ocispec.Descriptor) func() error {
+		return func() error {
+			if err := c8dimages.Dispatch(egCtx, topHandlers, nil, d); err != nil {
+				return err
+			}
+			lp.markReady(d.Digest)
+			return nil
 		}
-		progress.Update(out, id, "Complete")
-		return nil, nil
 	}
+	eg.Go(fetchOne(manifest.Config))
+	for _, l := range manifest.Layers {
+		eg.Go(fetchOne(l))
+	}
+
+	// The extractor runs on its own goroutine rather than through eg: eg.SetLimit bounds
+	// concurrent layer downloads, and counting the extractor against that same limit would
+	// permanently occupy one of only maxConcurrent download slots for the rest of the pull
+	// once extraction starts. It also gets its own context: egCtx is canceled as soon as
+	// eg.Wait returns, while layers may still be waiting to be applied.
+	extractCtx, cancelExtract := context.WithCancel(ctx)
+	defer cancelExtract()
+	extractDone := make(chan error, 1)
+	go func() {
+		extractDone <- lp.extract(extractCtx, pm.contentStore(), dir, out)
+	}()
+
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	if err := <-extractDone; err != nil {
+		return err
+	}
+	return removePullState(dir)
 }
 
 func childrenHandler(cs content.Store) c8dimages.HandlerFunc {
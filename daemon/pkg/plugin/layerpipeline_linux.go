@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/containerd/containerd/v2/core/content"
+	c8dimages "github.com/containerd/containerd/v2/core/images"
+	"github.com/moby/go-archive/chrootarchive"
+	"github.com/moby/moby/api/pkg/progress"
+	"github.com/moby/moby/v2/daemon/internal/stringid"
+	"github.com/moby/moby/v2/pkg/ioutils"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// defaultMaxConcurrentDownloads bounds how many plugin layers are downloaded
+// at once when PullOptions.MaxConcurrentDownloads is unset, matching the
+// default used for image pulls.
+const defaultMaxConcurrentDownloads = 3
+
+// layerPipeline lets layer downloads run concurrently while a single
+// goroutine applies them to a rootfs strictly in manifest order: download
+// order depends on network/registry scheduling, but rootfs correctness
+// depends on layers being applied in the order the image config says to
+// stack them.
+type layerPipeline struct {
+	layers []ocispec.Descriptor
+	ready  map[digest.Digest]chan struct{}
+
+	mu   sync.Mutex
+	seen map[digest.Digest]struct{}
+}
+
+func newLayerPipeline(layers []ocispec.Descriptor) *layerPipeline {
+	ready := make(map[digest.Digest]chan struct{}, len(layers))
+	for _, l := range layers {
+		ready[l.Digest] = make(chan struct{})
+	}
+	return &layerPipeline{
+		layers: layers,
+		ready:  ready,
+		seen:   make(map[digest.Digest]struct{}, len(layers)),
+	}
+}
+
+// markReady signals that d has finished downloading and is safe to read
+// from the content store. It is safe to call more than once for the same
+// digest (duplicate layers in a manifest share one descriptor).
+func (lp *layerPipeline) markReady(d digest.Digest) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	if _, ok := lp.seen[d]; ok {
+		return
+	}
+	lp.seen[d] = struct{}{}
+	if ch, ok := lp.ready[d]; ok {
+		close(ch)
+	}
+}
+
+// extract walks lp.layers in order, blocking on each one's ready channel,
+// and applies it to dir as soon as it's available.
+func (lp *layerPipeline) extract(ctx context.Context, cs content.Store, dir string, out progress.Output) error {
+	for _, l := range lp.layers {
+		select {
+		case <-lp.ready[l.Digest]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		switch l.MediaType {
+		case
+			ocispec.MediaTypeImageLayer,
+			c8dimages.MediaTypeDockerSchema2Layer,
+			ocispec.MediaTypeImageLayerGzip,
+			c8dimages.MediaTypeDockerSchema2LayerGzip,
+			c8dimages.MediaTypeDockerSchema2LayerForeignGzip:
+		default:
+			continue
+		}
+
+		if err := applyLayer(ctx, cs, dir, out, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readManifest fetches and unmarshals manifestDesc out of provider, giving
+// access to the manifest's config and ordered layer list.
+func readManifest(ctx context.Context, provider content.Provider, manifestDesc ocispec.Descriptor) (ocispec.Manifest, error) {
+	b, err := content.ReadBlob(ctx, provider, manifestDesc)
+	if err != nil {
+		return ocispec.Manifest{}, errors.Wrapf(err, "error reading plugin manifest %s", manifestDesc.Digest)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return ocispec.Manifest{}, errors.Wrapf(err, "error unmarshalling plugin manifest %s", manifestDesc.Digest)
+	}
+	return manifest, nil
+}
+
+// readIndex fetches and unmarshals indexDesc out of provider, giving access
+// to the descriptors it indexes (an image index's platform manifests, or a
+// referrers listing's signature/attestation manifests).
+func readIndex(ctx context.Context, provider content.Provider, indexDesc ocispec.Descriptor) (ocispec.Index, error) {
+	b, err := content.ReadBlob(ctx, provider, indexDesc)
+	if err != nil {
+		return ocispec.Index{}, errors.Wrapf(err, "error reading plugin index %s", indexDesc.Digest)
+	}
+	var idx ocispec.Index
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return ocispec.Index{}, errors.Wrapf(err, "error unmarshalling plugin index %s", indexDesc.Digest)
+	}
+	return idx, nil
+}
+
+// applyLayer reads a single fetched rootfs layer out of cs and applies it to
+// dir, reporting "Extracting"/"Complete" progress independently of download
+// progress.
+func applyLayer(ctx context.Context, cs content.Store, dir string, out progress.Output, desc ocispec.Descriptor) error {
+	ra, err := cs.ReaderAt(ctx, desc)
+	if err != nil {
+		return errors.Wrapf(err, "error getting content from content store for digest %s", desc.Digest)
+	}
+
+	id := stringid.TruncateID(desc.Digest.String())
+
+	rc := ioutils.NewReadCloserWrapper(content.NewReader(ra), ra.Close)
+	pr := progress.NewProgressReader(rc, out, desc.Size, id, "Extracting")
+	defer pr.Close()
+
+	if _, err := chrootarchive.ApplyLayer(dir, pr); err != nil {
+		return errors.Wrapf(err, "error applying layer for digest %s", desc.Digest)
+	}
+	progress.Update(out, id, "Complete")
+	return nil
+}
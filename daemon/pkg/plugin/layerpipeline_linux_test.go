@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content/local"
+	"github.com/moby/moby/api/pkg/progress"
+	"github.com/moby/moby/v2/daemon/internal/stringid"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+// tarLayer builds a minimal valid tar stream containing a single empty file,
+// just enough for chrootarchive.ApplyLayer to accept it.
+func tarLayer(t *testing.T, name string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	assert.NilError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: 0}))
+	assert.NilError(t, tw.Close())
+	return buf.Bytes()
+}
+
+// TestLayerPipelineAppliesInOrder publishes layers to the content store out
+// of manifest order and verifies they are still applied in manifest order.
+func TestLayerPipelineAppliesInOrder(t *testing.T) {
+	dir := t.TempDir()
+	cs, err := local.NewStore(filepath.Join(dir, "content"))
+	assert.NilError(t, err)
+
+	first := writeBlob(t, cs, ocispec.MediaTypeImageLayer, tarLayer(t, "first"))
+	second := writeBlob(t, cs, ocispec.MediaTypeImageLayer, tarLayer(t, "second"))
+
+	lp := newLayerPipeline([]ocispec.Descriptor{first, second})
+
+	applyDir := filepath.Join(dir, "rootfs")
+	assert.NilError(t, os.MkdirAll(applyDir, 0o755))
+
+	ch := make(chan progress.Progress, 8)
+	out := progress.ChanOutput(ch)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- lp.extract(context.Background(), cs, applyDir, out)
+	}()
+
+	// Signal readiness in reverse of manifest order; extract must still apply
+	// "first" before "second".
+	lp.markReady(second.Digest)
+	time.Sleep(10 * time.Millisecond)
+	lp.markReady(first.Digest)
+
+	select {
+	case err := <-done:
+		assert.NilError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for layer pipeline to extract")
+	}
+	close(ch)
+
+	var completedOrder []string
+	for p := range ch {
+		if p.Action == "Complete" {
+			completedOrder = append(completedOrder, p.ID)
+		}
+	}
+	assert.DeepEqual(t, completedOrder, []string{
+		stringid.TruncateID(first.Digest.String()),
+		stringid.TruncateID(second.Digest.String()),
+	})
+}
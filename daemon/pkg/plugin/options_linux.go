@@ -0,0 +1,31 @@
+package plugin
+
+import (
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// PullOptions holds the options that control how a plugin reference is
+// resolved and fetched.
+type PullOptions struct {
+	// Platform is the platform to select when the resolved reference
+	// points at a multi-platform manifest list or OCI index.
+	//
+	// If nil, the platform of the host running the daemon is used.
+	//
+	// Nothing in this package sets Platform from a request yet: that needs
+	// a --platform flag on the plugin install/pull API and CLI, neither of
+	// which lives in this tree (the CLI flag belongs to the separate
+	// docker/cli repository).
+	Platform *ocispec.Platform
+
+	// MaxConcurrentDownloads is the maximum number of layers fetched at
+	// once.
+	//
+	// If zero, defaultMaxConcurrentDownloads is used.
+	MaxConcurrentDownloads int
+
+	// DisableContentTrust skips Manager's configured SignatureVerifier for
+	// this pull, mirroring the `--disable-content-trust` flag on image
+	// pulls. It has no effect when the Manager has no verifier configured.
+	DisableContentTrust bool
+}
@@ -0,0 +1,95 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/distribution/reference"
+	"github.com/moby/moby/v2/pkg/ioutils"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// pullStateFile is the name fetch and Resume persist a pull's pullState
+// under, inside the directory the plugin's layers are applied to.
+const pullStateFile = "pull-state.json"
+
+// pullState is the durable record of an in-progress plugin pull: enough to
+// rebuild the fetch after a daemon restart and resume it against the exact
+// manifest originally resolved, rather than whatever manifest a fresh
+// resolve of a since-moved tag would return.
+type pullState struct {
+	Ref               string          `json:"ref"`
+	AuthScope         string          `json:"authScope"`
+	ManifestDigest    digest.Digest   `json:"manifestDigest"`
+	ManifestSize      int64           `json:"manifestSize"`
+	ManifestMediaType string          `json:"manifestMediaType"`
+	ConfigDigest      digest.Digest   `json:"configDigest"`
+	BlobDigests       []digest.Digest `json:"blobDigests"`
+}
+
+// newPullState builds the pullState to persist for a pull of ref once its
+// manifest has been resolved and fetched.
+func newPullState(ref reference.Named, manifestDesc ocispec.Descriptor, manifest ocispec.Manifest) pullState {
+	blobs := make([]digest.Digest, 0, len(manifest.Layers))
+	for _, l := range manifest.Layers {
+		blobs = append(blobs, l.Digest)
+	}
+	return pullState{
+		Ref:               ref.String(),
+		AuthScope:         scope(ref, false),
+		ManifestDigest:    manifestDesc.Digest,
+		ManifestSize:      manifestDesc.Size,
+		ManifestMediaType: manifestDesc.MediaType,
+		ConfigDigest:      manifest.Config.Digest,
+		BlobDigests:       blobs,
+	}
+}
+
+// manifestDescriptor rebuilds the descriptor fetch originally resolved for
+// this pull, as needed to read the manifest back out of the content store.
+func (s pullState) manifestDescriptor() ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType: s.ManifestMediaType,
+		Digest:    s.ManifestDigest,
+		Size:      s.ManifestSize,
+	}
+}
+
+// savePullState persists state to dir, overwriting any state recorded for
+// an earlier pull into the same directory.
+//
+// It writes through a temporary file and renames it into place so a crash
+// or restart mid-write can't leave a truncated pull-state.json behind:
+// exactly the failure this state exists to let a restart recover from.
+func savePullState(dir string, state pullState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling plugin pull state")
+	}
+	return ioutils.AtomicWriteFile(filepath.Join(dir, pullStateFile), b, 0o600)
+}
+
+// loadPullState reads back a pullState previously written by savePullState.
+func loadPullState(dir string) (pullState, error) {
+	b, err := os.ReadFile(filepath.Join(dir, pullStateFile))
+	if err != nil {
+		return pullState{}, err
+	}
+	var state pullState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return pullState{}, errors.Wrap(err, "error unmarshalling plugin pull state")
+	}
+	return state, nil
+}
+
+// removePullState deletes a persisted pullState once the pull it describes
+// has completed and no longer needs to be resumable.
+func removePullState(dir string) error {
+	if err := os.Remove(filepath.Join(dir, pullStateFile)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "error removing plugin pull state")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+// TestPullStateRoundTrip verifies that a pullState saved by fetch can be
+// read back unchanged by Resume after a simulated daemon restart.
+func TestPullStateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	ref, err := reference.ParseNormalizedNamed("example.com/plugin:latest")
+	assert.NilError(t, err)
+
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("manifest"),
+		Size:      42,
+	}
+	manifest := ocispec.Manifest{
+		Config: ocispec.Descriptor{Digest: digest.FromString("config")},
+		Layers: []ocispec.Descriptor{
+			{Digest: digest.FromString("layer1")},
+			{Digest: digest.FromString("layer2")},
+		},
+	}
+
+	want := newPullState(ref, manifestDesc, manifest)
+	assert.NilError(t, savePullState(dir, want))
+
+	got, err := loadPullState(dir)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, want)
+	assert.DeepEqual(t, got.manifestDescriptor(), manifestDesc)
+
+	assert.NilError(t, removePullState(dir))
+	_, err = loadPullState(dir)
+	assert.ErrorContains(t, err, "no such file")
+}
+
+// TestResumeRejectsMismatchedReference verifies that Resume refuses to pick
+// up a persisted pull when it's asked to resume a different reference than
+// the one that was pulling.
+func TestResumeRejectsMismatchedReference(t *testing.T) {
+	dir := t.TempDir()
+
+	pulling, err := reference.ParseNormalizedNamed("example.com/plugin:latest")
+	assert.NilError(t, err)
+	other, err := reference.ParseNormalizedNamed("example.com/other:latest")
+	assert.NilError(t, err)
+
+	manifestDesc := ocispec.Descriptor{Digest: digest.FromString("manifest"), Size: 1}
+	assert.NilError(t, savePullState(dir, newPullState(pulling, manifestDesc, ocispec.Manifest{})))
+
+	pm := &Manager{}
+	err = pm.Resume(context.Background(), other, nil, nil, nil, PullOptions{}, dir)
+	assert.ErrorContains(t, err, "is for")
+}
@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/content"
+	c8dimages "github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/platforms"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// platformManifestHandler filters the children of a manifest list or OCI
+// index down to the single manifest that best matches the requested
+// platform, falling back to childrenHandler unmodified for every other
+// media type.
+//
+// It returns an error listing the platforms that were actually available
+// when none of them satisfy the request, since the caller has no other
+// way to tell a genuine "plugin unavailable for this arch" apart from a
+// malformed index.
+func platformManifestHandler(cs content.Store, p platforms.MatchComparer) c8dimages.HandlerFunc {
+	children := childrenHandler(cs)
+	return func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		switch desc.MediaType {
+		case ocispec.MediaTypeImageIndex, c8dimages.MediaTypeDockerSchema2ManifestList:
+		default:
+			return children(ctx, desc)
+		}
+
+		all, err := children(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+
+		selected, err := selectPlatformManifest(all, p)
+		if err != nil {
+			return nil, err
+		}
+		return []ocispec.Descriptor{selected}, nil
+	}
+}
+
+// selectPlatformManifest picks the descriptor out of candidates (the
+// manifests referenced by an index or manifest list) that best matches p.
+//
+// When more than one candidate matches, it prefers whichever one the
+// matcher itself ranks best, mirroring how the image puller picks among
+// compatible candidates (e.g. a variant-less request matching several arm
+// variants).
+func selectPlatformManifest(candidates []ocispec.Descriptor, p platforms.MatchComparer) (ocispec.Descriptor, error) {
+	var matched []ocispec.Descriptor
+	for _, d := range candidates {
+		if d.Platform != nil && p.Match(*d.Platform) {
+			matched = append(matched, d)
+		}
+	}
+	if len(matched) == 0 {
+		return ocispec.Descriptor{}, errors.Errorf("no plugin manifest found matching the requested platform (available: %s)", availablePlatforms(candidates))
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return p.Less(*matched[i].Platform, *matched[j].Platform)
+	})
+	return matched[0], nil
+}
+
+func availablePlatforms(descs []ocispec.Descriptor) string {
+	var ps []string
+	for _, d := range descs {
+		if d.Platform == nil {
+			continue
+		}
+		ps = append(ps, platforms.Format(*d.Platform))
+	}
+	if len(ps) == 0 {
+		return "none"
+	}
+	return strings.Join(ps, ", ")
+}
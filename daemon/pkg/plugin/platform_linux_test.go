@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/content/local"
+	"github.com/containerd/platforms"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"gotest.tools/v3/assert"
+)
+
+// writeBlob ingests b into cs under mt and returns its descriptor.
+func writeBlob(t *testing.T, cs content.Store, mt string, b []byte) ocispec.Descriptor {
+	t.Helper()
+	dgst := digest.FromBytes(b)
+	desc := ocispec.Descriptor{MediaType: mt, Digest: dgst, Size: int64(len(b))}
+	ctx := context.Background()
+	w, err := cs.Writer(ctx, content.WithRef(dgst.String()), content.WithDescriptor(desc))
+	assert.NilError(t, err)
+	_, err = w.Write(b)
+	assert.NilError(t, err)
+	assert.NilError(t, w.Commit(ctx, desc.Size, desc.Digest))
+	assert.NilError(t, w.Close())
+	return desc
+}
+
+// TestPlatformManifestHandlerSelectsMatchingPlugin publishes a fake OCI index
+// referencing two fake plugin manifests (amd64 and arm64) into a local
+// content store and verifies platformManifestHandler picks the manifest for
+// the requested platform.
+func TestPlatformManifestHandlerSelectsMatchingPlugin(t *testing.T) {
+	dir := t.TempDir()
+	cs, err := local.NewStore(dir)
+	assert.NilError(t, err)
+
+	amd64Manifest := writeBlob(t, cs, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2,"config":{},"annotations":{"arch":"amd64"}}`))
+	arm64Manifest := writeBlob(t, cs, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2,"config":{},"annotations":{"arch":"arm64"}}`))
+
+	index := ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{
+			withPlatform(amd64Manifest, ocispec.Platform{OS: "linux", Architecture: "amd64"}),
+			withPlatform(arm64Manifest, ocispec.Platform{OS: "linux", Architecture: "arm64"}),
+		},
+	}
+	b, err := json.Marshal(index)
+	assert.NilError(t, err)
+	indexDesc := writeBlob(t, cs, ocispec.MediaTypeImageIndex, b)
+
+	handler := platformManifestHandler(cs, platforms.OnlyStrict(ocispec.Platform{OS: "linux", Architecture: "arm64"}))
+	children, err := handler(context.Background(), indexDesc)
+	assert.NilError(t, err)
+	assert.Equal(t, len(children), 1)
+	assert.Equal(t, children[0].Digest, arm64Manifest.Digest)
+}
+
+// TestPlatformManifestHandlerNoMatch verifies that requesting a platform not
+// present in the index produces an error listing what was actually available.
+func TestPlatformManifestHandlerNoMatch(t *testing.T) {
+	dir := t.TempDir()
+	cs, err := local.NewStore(dir)
+	assert.NilError(t, err)
+
+	amd64Manifest := writeBlob(t, cs, ocispec.MediaTypeImageManifest, []byte(`{"schemaVersion":2,"config":{}}`))
+	index := ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{
+			withPlatform(amd64Manifest, ocispec.Platform{OS: "linux", Architecture: "amd64"}),
+		},
+	}
+	b, err := json.Marshal(index)
+	assert.NilError(t, err)
+	indexDesc := writeBlob(t, cs, ocispec.MediaTypeImageIndex, b)
+
+	handler := platformManifestHandler(cs, platforms.OnlyStrict(ocispec.Platform{OS: "linux", Architecture: "arm64"}))
+	_, err = handler(context.Background(), indexDesc)
+	assert.ErrorContains(t, err, "linux/amd64")
+}
+
+func withPlatform(desc ocispec.Descriptor, p ocispec.Platform) ocispec.Descriptor {
+	desc.Platform = &p
+	return desc
+}
@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+
+	c8dimages "github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/distribution/reference"
+	"github.com/moby/moby/api/pkg/progress"
+	"github.com/moby/moby/api/types/registry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Resume picks a plugin pull for ref back up from wherever it was left off,
+// using the pullState fetch persisted into dir before it started downloading
+// layers. It re-runs the same layer pipeline fetch uses. ref is resolved
+// again only to obtain a fetcher; the descriptor that resolve returns is
+// discarded and the pipeline dispatches against the cached manifest digest,
+// so a tag that has since moved doesn't cause Resume to fetch a different
+// manifest than the one originally pulled.
+//
+// Blobs pm.contentStore() already has complete are skipped by the fetch
+// handler's own existence check; anything left partially written resumes
+// the same way any other interrupted containerd fetch does, via the
+// content store's writer offset.
+//
+// Resume returns an error if dir has no persisted pull state, or if that
+// state is for a different reference than ref.
+func (pm *Manager) Resume(ctx context.Context, ref reference.Named, auth *registry.AuthConfig, out progress.Output, metaHeader http.Header, opts PullOptions, dir string) error {
+	withDomain, err := reference.ParseNormalizedNamed(ref.String())
+	if err != nil {
+		return errors.Wrap(err, "error parsing plugin image reference")
+	}
+
+	state, err := loadPullState(dir)
+	if err != nil {
+		return errors.Wrap(err, "no pending plugin pull to resume")
+	}
+	if state.Ref != withDomain.String() {
+		return errors.Errorf("pending pull in %s is for %s, not %s", dir, state.Ref, withDomain)
+	}
+
+	ctx = docker.WithScope(ctx, state.AuthScope)
+	ctx = remotes.WithMediaTypeKeyPrefix(ctx, mediaTypePluginConfig, "docker-plugin")
+
+	resolver, err := pm.newResolver(ctx, nil, auth, metaHeader, false)
+	if err != nil {
+		return err
+	}
+	resolved, _, err := resolver.Resolve(ctx, withDomain.String())
+	if err != nil {
+		return errors.Wrap(err, "error resolving plugin reference")
+	}
+	fetcher, err := resolver.Fetcher(ctx, resolved)
+	if err != nil {
+		return errors.Wrap(err, "error creating plugin image fetcher")
+	}
+
+	cs := pm.contentStore()
+	manifestDesc := state.manifestDescriptor()
+	manifest, err := readManifest(ctx, cs, manifestDesc)
+	if err != nil {
+		return errors.Wrap(err, "plugin manifest is no longer in the content store; pull cannot be resumed")
+	}
+
+	// Re-verify against whatever SignatureVerifier and opts.DisableContentTrust are current
+	// today, not whatever was current when the original pull was interrupted: otherwise
+	// tightening plugins.verification.policy between the interrupted pull and the daemon
+	// restart that resumes it would never be enforced for this plugin.
+	if err := pm.verifySignature(ctx, withDomain, manifestDesc, opts); err != nil {
+		return errors.Wrap(err, "plugin signature verification failed")
+	}
+
+	fp := withFetchProgress(cs, out, withDomain)
+	fetchHandler := remotes.FetchHandler(cs, fetcher)
+	topHandlers := c8dimages.Handlers(fp, fetchHandler)
+
+	maxConcurrent := opts.MaxConcurrentDownloads
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultMaxConcurrentDownloads
+	}
+
+	lp := newLayerPipeline(manifest.Layers)
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(maxConcurrent)
+
+	fetchOne := func(d ocispec.Descriptor) func() error {
+		return func() error {
+			if err := c8dimages.Dispatch(egCtx, topHandlers, nil, d); err != nil {
+				return err
+			}
+			lp.markReady(d.Digest)
+			return nil
+		}
+	}
+	eg.Go(fetchOne(manifest.Config))
+	for _, l := range manifest.Layers {
+		eg.Go(fetchOne(l))
+	}
+	// See fetch's identical comment: keep the extractor off the limited errgroup so it doesn't
+	// permanently occupy one of only maxConcurrent download slots once it starts, and give it
+	// a context that eg.Wait does not cancel.
+	extractCtx, cancelExtract := context.WithCancel(ctx)
+	defer cancelExtract()
+	extractDone := make(chan error, 1)
+	go func() {
+		extractDone <- lp.extract(extractCtx, cs, dir, out)
+	}()
+
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	if err := <-extractDone; err != nil {
+		return err
+	}
+	return removePullState(dir)
+}
@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/pkg/errors"
+)
+
+// VerificationPolicy selects which signature scheme, if any, a plugin pull
+// must satisfy. It mirrors the daemon's plugins.verification.policy config
+// field.
+type VerificationPolicy string
+
+const (
+	// VerificationPolicyNone performs no signature verification.
+	VerificationPolicyNone VerificationPolicy = ""
+	// VerificationPolicyCosign requires a valid cosign signature.
+	VerificationPolicyCosign VerificationPolicy = "cosign"
+	// VerificationPolicyNotation requires a valid Notation v2 signature.
+	VerificationPolicyNotation VerificationPolicy = "notation"
+)
+
+// NewSignatureVerifier builds the SignatureVerifier described by policy,
+// for use as Manager's verifier. pemKeys is the daemon's
+// plugins.verification.keys config field, PEM-encoded: a set of public keys
+// for VerificationPolicyCosign, or a set of trusted root certificates for
+// VerificationPolicyNotation. newResolver is normally Manager.newResolver so
+// the verifier fetches signature artifacts with the same registry auth as
+// the plugin image itself.
+//
+// It returns a nil SignatureVerifier, nil error for VerificationPolicyNone.
+func NewSignatureVerifier(policy VerificationPolicy, pemKeys []byte, newResolver func(ctx context.Context) (remotes.Resolver, error)) (SignatureVerifier, error) {
+	switch policy {
+	case VerificationPolicyNone:
+		return nil, nil
+	case VerificationPolicyCosign:
+		keys, err := ParseCosignPublicKeys(pemKeys)
+		if err != nil {
+			return nil, err
+		}
+		return &CosignVerifier{Keys: keys, NewResolver: newResolver}, nil
+	case VerificationPolicyNotation:
+		trust := x509.NewCertPool()
+		if ok := trust.AppendCertsFromPEM(pemKeys); !ok {
+			return nil, errors.New("no PEM trusted certificates found for notation verification policy")
+		}
+		return &NotationVerifier{TrustStore: trust, NewResolver: newResolver}, nil
+	default:
+		return nil, errors.Errorf("unknown plugin verification policy %q", policy)
+	}
+}
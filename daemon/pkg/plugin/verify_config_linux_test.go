@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/remotes"
+	"gotest.tools/v3/assert"
+)
+
+const testCosignKeyPEM = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEWXgPNuTIzav6lIy5uAXyr6OXm9Iw
+Zv9JS0+1gIAxSC/CoP36ZzUrlN1i/vOQmeryDwlrsm7J44yzMsuz4RHGog==
+-----END PUBLIC KEY-----`
+
+const testNotationTrustPEM = `-----BEGIN CERTIFICATE-----
+MIIBcjCCARmgAwIBAgIUXJyofuV2fDywbrJCZr54oY83rbcwCgYIKoZIzj0EAwIw
+DzENMAsGA1UEAwwEdGVzdDAeFw0yNjA3MzAxNTE3MTVaFw0zNjA3MjcxNTE3MTVa
+MA8xDTALBgNVBAMMBHRlc3QwWTATBgcqhkjOPQIBBggqhkjOPQMBBwNCAASfGeD5
+ye6rW2qPFtenOV/ATAsGefQ+YuyluDh1ruApu8Lk5UBXHXhuR85kttWKooNpP5qX
+C81tmiVBpyTEf9FNo1MwUTAdBgNVHQ4EFgQUxAHX1Y9x+TAlg8vMpawJt1Zp5mcw
+HwYDVR0jBBgwFoAUxAHX1Y9x+TAlg8vMpawJt1Zp5mcwDwYDVR0TAQH/BAUwAwEB
+/zAKBggqhkjOPQQDAgNHADBEAiAW8Vq93JegaSkla7A1gq1rGqKUDgTW7R4GqKg0
+a1VyaQIgZDm0Yl+F6l0DJFP+mTyT07Na+uVCAS5xmOvHLfYOxKI=
+-----END CERTIFICATE-----`
+
+func noResolver(context.Context) (remotes.Resolver, error) { return nil, nil }
+
+func TestNewSignatureVerifierNone(t *testing.T) {
+	v, err := NewSignatureVerifier(VerificationPolicyNone, nil, noResolver)
+	assert.NilError(t, err)
+	assert.Equal(t, v, nil)
+}
+
+func TestNewSignatureVerifierCosign(t *testing.T) {
+	v, err := NewSignatureVerifier(VerificationPolicyCosign, []byte(testCosignKeyPEM), noResolver)
+	assert.NilError(t, err)
+	cv, ok := v.(*CosignVerifier)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, len(cv.Keys), 1)
+}
+
+func TestNewSignatureVerifierCosignRequiresKeys(t *testing.T) {
+	_, err := NewSignatureVerifier(VerificationPolicyCosign, nil, noResolver)
+	assert.ErrorContains(t, err, "no PEM public keys found")
+}
+
+func TestNewSignatureVerifierNotation(t *testing.T) {
+	v, err := NewSignatureVerifier(VerificationPolicyNotation, []byte(testNotationTrustPEM), noResolver)
+	assert.NilError(t, err)
+	nv, ok := v.(*NotationVerifier)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, len(nv.TrustStore.Subjects()), 1) //nolint:staticcheck // Subjects is deprecated but fine for counting entries in a test
+}
+
+func TestNewSignatureVerifierNotationRequiresTrustedCerts(t *testing.T) {
+	_, err := NewSignatureVerifier(VerificationPolicyNotation, nil, noResolver)
+	assert.ErrorContains(t, err, "no PEM trusted certificates found")
+}
+
+func TestNewSignatureVerifierUnknownPolicy(t *testing.T) {
+	_, err := NewSignatureVerifier(VerificationPolicy("bogus"), nil, noResolver)
+	assert.ErrorContains(t, err, "unknown plugin verification policy")
+}
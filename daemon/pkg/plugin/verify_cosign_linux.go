@@ -0,0 +1,163 @@
+package plugin
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+
+	"github.com/containerd/containerd/v2/core/content"
+	c8dimages "github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/distribution/reference"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// simpleSigningPayload is the "simple signing" payload cosign stores as the
+// sole layer of a signature manifest. The base64 signature over these bytes
+// is carried separately, in the layer's dev.cosignproject.cosign/signature
+// annotation; only the signed manifest digest is decoded from the payload.
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// CosignVerifier verifies plugin manifests signed cosign-style: the
+// signature lives in a companion "<algo>-<hex>.sig" tag pushed to the same
+// repository as the plugin image.
+//
+// It does not verify a Rekor transparency-log inclusion proof; only the
+// cases listed in Keys are checked. Add Rekor verification here once the
+// daemon has a configured log URL to check against.
+type CosignVerifier struct {
+	// Keys are the public keys a signature must verify against. A manifest
+	// is trusted if at least one key matches.
+	Keys []crypto.PublicKey
+
+	// NewResolver builds the resolver used to fetch the signature tag. It
+	// is normally Manager.newResolver bound to the daemon's registry auth
+	// so the signature tag is fetched with the same credentials as the
+	// plugin image itself.
+	NewResolver func(ctx context.Context) (remotes.Resolver, error)
+}
+
+func (v *CosignVerifier) Verify(ctx context.Context, ref reference.Named, manifestDesc ocispec.Descriptor, cs content.Store) error {
+	if len(v.Keys) == 0 {
+		return errors.New("cosign verification is enabled but no public keys are configured")
+	}
+
+	sigRef, err := signatureTagFor(ref, manifestDesc.Digest.String())
+	if err != nil {
+		return err
+	}
+
+	resolver, err := v.NewResolver(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error creating resolver for plugin signature")
+	}
+
+	resolved, sigManifestDesc, err := resolver.Resolve(ctx, sigRef.String())
+	if err != nil {
+		return errors.Wrapf(err, "no signature found for plugin manifest %s", manifestDesc.Digest)
+	}
+
+	fetcher, err := resolver.Fetcher(ctx, resolved)
+	if err != nil {
+		return errors.Wrap(err, "error creating fetcher for plugin signature")
+	}
+
+	fetchHandler := remotes.FetchHandler(cs, fetcher)
+	if err := c8dimages.Dispatch(ctx, c8dimages.Handlers(fetchHandler, c8dimages.ChildrenHandler(cs)), nil, sigManifestDesc); err != nil {
+		return errors.Wrap(err, "error fetching plugin signature manifest")
+	}
+
+	sigManifest, err := readManifest(ctx, cs, sigManifestDesc)
+	if err != nil {
+		return err
+	}
+	if len(sigManifest.Layers) != 1 {
+		return errors.Errorf("expected exactly one signature layer for %s, got %d", manifestDesc.Digest, len(sigManifest.Layers))
+	}
+	layer := sigManifest.Layers[0]
+
+	b64Sig, ok := layer.Annotations["dev.cosignproject.cosign/signature"]
+	if !ok {
+		return errors.Errorf("signature manifest for %s is missing the cosign signature annotation", manifestDesc.Digest)
+	}
+	sig, err := base64.StdEncoding.DecodeString(b64Sig)
+	if err != nil {
+		return errors.Wrap(err, "error decoding cosign signature")
+	}
+
+	payload, err := content.ReadBlob(ctx, cs, layer)
+	if err != nil {
+		return errors.Wrap(err, "error reading cosign signature payload")
+	}
+	var simple simpleSigningPayload
+	if err := json.Unmarshal(payload, &simple); err != nil {
+		return errors.Wrap(err, "error parsing cosign signature payload")
+	}
+	if simple.Critical.Image.DockerManifestDigest != manifestDesc.Digest.String() {
+		return errors.Errorf("cosign signature payload is for %s, not %s", simple.Critical.Image.DockerManifestDigest, manifestDesc.Digest)
+	}
+
+	digest := sha256.Sum256(payload)
+	for _, key := range v.Keys {
+		if verifyCosignSignature(key, digest[:], sig) {
+			return nil
+		}
+	}
+	return errors.Errorf("no configured key verifies the signature for plugin manifest %s", manifestDesc.Digest)
+}
+
+func verifyCosignSignature(key crypto.PublicKey, digest, sig []byte) bool {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest, sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, sig) == nil
+	default:
+		return false
+	}
+}
+
+// ParseCosignPublicKeys decodes one or more PEM-encoded public keys, as
+// found in the daemon's `plugins.verification.keys` configuration.
+func ParseCosignPublicKeys(pemData []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			break
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "error parsing plugin verification public key")
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM public keys found in plugin verification configuration")
+	}
+	return keys, nil
+}
+
+// signatureTagFor builds the reference cosign publishes a manifest's
+// signature under: the same repository, tagged "<algo>-<hex>.sig".
+func signatureTagFor(ref reference.Named, digest string) (reference.Named, error) {
+	tag, err := sigTagFromDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	return reference.WithTag(reference.TrimNamed(ref), tag+".sig")
+}
@@ -0,0 +1,44 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/distribution/reference"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// SignatureVerifier checks that a plugin manifest resolved from a registry
+// is trusted before any of its content is fetched or applied.
+//
+// Verify is called with the already-resolved manifest descriptor (the
+// concrete manifest fetch will use, not an index/manifest list) and the
+// content store fetch writes into; implementations that need additional
+// blobs, such as a companion signature manifest, fetch them into the same
+// store using their own resolver.
+type SignatureVerifier interface {
+	Verify(ctx context.Context, ref reference.Named, manifestDesc ocispec.Descriptor, cs content.Store) error
+}
+
+// verifySignature runs pm's configured SignatureVerifier, if any, against
+// manifestDesc. It is a no-op when no verifier is configured or content
+// trust has been explicitly disabled for this pull.
+func (pm *Manager) verifySignature(ctx context.Context, ref reference.Named, manifestDesc ocispec.Descriptor, opts PullOptions) error {
+	if opts.DisableContentTrust || pm.verifier == nil {
+		return nil
+	}
+	return pm.verifier.Verify(ctx, ref, manifestDesc, pm.contentStore())
+}
+
+// sigTagFromDigest turns a "<algo>:<hex>" digest into the "<algo>-<hex>"
+// form used to tag signature artifacts: the OCI 1.1 referrers tag-schema
+// fallback, and the cosign signature tag convention built on top of it.
+func sigTagFromDigest(digest string) (string, error) {
+	algo, hex, ok := strings.Cut(digest, ":")
+	if !ok || algo == "" || hex == "" {
+		return "", errors.Errorf("malformed digest %q", digest)
+	}
+	return algo + "-" + hex, nil
+}
@@ -0,0 +1,177 @@
+package plugin
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+
+	"github.com/containerd/containerd/v2/core/content"
+	c8dimages "github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/distribution/reference"
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-core-go/signature/jws"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// mediaTypeNotationSignature is the artifact type Notation v2 publishes its
+// signature manifests under.
+const mediaTypeNotationSignature = "application/vnd.cncf.notary.signature"
+
+// NotationVerifier verifies plugin manifests signed Notation-style: the
+// signature is a separate OCI manifest referring back to the plugin
+// manifest through the OCI 1.1 referrers API (or its tag-schema fallback,
+// "<alg>-<hex>"), with a single JWS-wrapped signature envelope as its
+// layer. Verify looks signatures up through the tag-schema fallback tag
+// only; it does not query the referrers API.
+//
+// It evaluates the envelope's certificate chain against TrustStore; it does
+// not evaluate a notation trust policy document, so every certificate in
+// TrustStore is treated as equally authorized to sign any plugin.
+type NotationVerifier struct {
+	// TrustStore holds the certificates a signature's chain must verify
+	// against.
+	TrustStore *x509.CertPool
+
+	// NewResolver builds the resolver used to fetch the referrers listing
+	// and signature manifest. It is normally Manager.newResolver bound to
+	// the daemon's registry auth so the signature is fetched with the same
+	// credentials as the plugin image itself.
+	NewResolver func(ctx context.Context) (remotes.Resolver, error)
+}
+
+func (v *NotationVerifier) Verify(ctx context.Context, ref reference.Named, manifestDesc ocispec.Descriptor, cs content.Store) error {
+	if v.TrustStore == nil {
+		return errors.New("notation verification is enabled but no trust store is configured")
+	}
+
+	referrersRef, err := referrersTagFor(ref, manifestDesc.Digest.String())
+	if err != nil {
+		return err
+	}
+
+	resolver, err := v.NewResolver(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error creating resolver for plugin signature")
+	}
+
+	resolved, referrersDesc, err := resolver.Resolve(ctx, referrersRef.String())
+	if err != nil {
+		return errors.Wrapf(err, "no signatures found for plugin manifest %s", manifestDesc.Digest)
+	}
+
+	fetcher, err := resolver.Fetcher(ctx, resolved)
+	if err != nil {
+		return errors.Wrap(err, "error creating fetcher for plugin signature")
+	}
+
+	fetchHandler := remotes.FetchHandler(cs, fetcher)
+	childrenHandler := c8dimages.Handlers(fetchHandler, c8dimages.ChildrenHandler(cs))
+	if err := c8dimages.Dispatch(ctx, childrenHandler, nil, referrersDesc); err != nil {
+		return errors.Wrap(err, "error fetching plugin signature referrers")
+	}
+
+	referrers, err := readIndex(ctx, cs, referrersDesc)
+	if err != nil {
+		return err
+	}
+
+	sigDesc, ok := findReferrer(referrers.Manifests, mediaTypeNotationSignature)
+	if !ok {
+		return errors.Errorf("no notation signature found for plugin manifest %s", manifestDesc.Digest)
+	}
+
+	if err := c8dimages.Dispatch(ctx, childrenHandler, nil, sigDesc); err != nil {
+		return errors.Wrap(err, "error fetching plugin signature manifest")
+	}
+
+	sigManifest, err := readManifest(ctx, cs, sigDesc)
+	if err != nil {
+		return err
+	}
+	if len(sigManifest.Layers) != 1 {
+		return errors.Errorf("expected exactly one signature layer for %s, got %d", manifestDesc.Digest, len(sigManifest.Layers))
+	}
+	layer := sigManifest.Layers[0]
+	if layer.MediaType != jws.MediaTypeEnvelope {
+		return errors.Errorf("unexpected media type %q for notation signature layer", layer.MediaType)
+	}
+
+	envelope, err := content.ReadBlob(ctx, cs, layer)
+	if err != nil {
+		return errors.Wrap(err, "error reading notation signature envelope")
+	}
+
+	sigEnv, err := signature.ParseEnvelope(jws.MediaTypeEnvelope, envelope)
+	if err != nil {
+		return errors.Wrap(err, "error parsing notation signature envelope")
+	}
+	envContent, err := sigEnv.Verify()
+	if err != nil {
+		return errors.Wrap(err, "notation signature verification failed")
+	}
+	if err := verifyNotationChain(envContent.SignerInfo.CertificateChain, v.TrustStore); err != nil {
+		return err
+	}
+
+	var payload notationPayload
+	if err := json.Unmarshal(envContent.Payload.Content, &payload); err != nil {
+		return errors.Wrap(err, "error parsing notation signature payload")
+	}
+	if payload.TargetArtifact.Digest != manifestDesc.Digest.String() {
+		return errors.Errorf("notation signature payload is for %s, not %s", payload.TargetArtifact.Digest, manifestDesc.Digest)
+	}
+	return nil
+}
+
+// notationPayload is the subset of the Notation v2 signature payload
+// (https://github.com/notaryproject/specifications/blob/main/specs/signature-specification.md#payload)
+// needed to tie a verified signature back to the plugin manifest it covers.
+type notationPayload struct {
+	TargetArtifact struct {
+		Digest string `json:"digest"`
+	} `json:"targetArtifact"`
+}
+
+// verifyNotationChain checks that the leaf of chain verifies against trust,
+// without evaluating revocation or a trust policy's signing scope.
+func verifyNotationChain(chain []*x509.Certificate, trust *x509.CertPool) error {
+	if len(chain) == 0 {
+		return errors.New("notation signature has no certificate chain")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         trust,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return errors.Wrap(err, "notation signature certificate does not chain to a trusted root")
+	}
+	return nil
+}
+
+// referrersTagFor builds the OCI 1.1 referrers tag-schema fallback
+// reference for digest: the same repository, tagged "<alg>-<hex>".
+func referrersTagFor(ref reference.Named, digest string) (reference.Named, error) {
+	tag, err := sigTagFromDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	return reference.WithTag(reference.TrimNamed(ref), tag)
+}
+
+// findReferrer returns the first descriptor in manifests whose artifact
+// type matches artifactType.
+func findReferrer(manifests []ocispec.Descriptor, artifactType string) (ocispec.Descriptor, bool) {
+	for _, d := range manifests {
+		if d.ArtifactType == artifactType {
+			return d, true
+		}
+	}
+	return ocispec.Descriptor{}, false
+}
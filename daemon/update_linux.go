@@ -1,14 +1,30 @@
 package daemon
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/containerd/cgroups/v3"
 	"github.com/moby/moby/api/types/container"
 	libcontainerdtypes "github.com/moby/moby/v2/daemon/internal/libcontainerd/types"
 	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
 )
 
-func toContainerdResources(resources container.Resources) *libcontainerdtypes.Resources {
+func toContainerdResources(resources container.Resources) (*libcontainerdtypes.Resources, error) {
+	if cgroups.Mode() == cgroups.Unified {
+		return toContainerdResourcesV2(resources)
+	}
+	return toContainerdResourcesV1(resources), nil
+}
+
+// toContainerdResourcesV1 builds the cgroup v1-shaped resources runc/crun
+// expect on hosts that haven't switched to the unified hierarchy.
+func toContainerdResourcesV1(resources container.Resources) *libcontainerdtypes.Resources {
 	var r libcontainerdtypes.Resources
 
 	if resources.BlkioWeight != 0 {
@@ -26,21 +42,7 @@ func toContainerdResources(resources container.Resources) *libcontainerdtypes.Re
 		cpu.Shares = &shares
 	}
 
-	var (
-		period uint64
-		quota  int64
-	)
-	if resources.NanoCPUs != 0 {
-		period = uint64(100 * time.Millisecond / time.Microsecond)
-		quota = resources.NanoCPUs * int64(period) / 1e9
-	}
-	if quota == 0 && resources.CPUQuota != 0 {
-		quota = resources.CPUQuota
-	}
-	if period == 0 && resources.CPUPeriod != 0 {
-		period = uint64(resources.CPUPeriod)
-	}
-
+	period, quota := cpuQuotaPeriod(resources)
 	if period != 0 {
 		cpu.Period = &period
 	}
@@ -73,3 +75,194 @@ func toContainerdResources(resources container.Resources) *libcontainerdtypes.Re
 	r.Pids = getPidsLimit(resources)
 	return &r
 }
+
+// toContainerdResourcesV2 builds the cgroup v2 resources for hosts running
+// the unified hierarchy. Rather than let runc/crun translate the v1-shaped
+// fields above (which loses information: v1 blkio weight is linearly
+// compressed into v2's io.weight range, and a v1 memory reservation doesn't
+// map cleanly onto v2's separate memory.low/memory.high knobs), it emits
+// the native v2 controls directly through Unified.
+func toContainerdResourcesV2(resources container.Resources) (*libcontainerdtypes.Resources, error) {
+	var r libcontainerdtypes.Resources
+	unified := map[string]string{}
+
+	if resources.BlkioWeight != 0 {
+		unified["io.weight"] = strconv.FormatUint(convertBlkioWeightToIOWeight(resources.BlkioWeight), 10)
+	}
+	ioMax, err := convertBlkioThrottleToIOMax(resources)
+	if err != nil {
+		return nil, err
+	}
+	if ioMax != "" {
+		unified["io.max"] = ioMax
+	}
+
+	if resources.MemoryReservation != 0 {
+		unified["memory.low"] = strconv.FormatInt(resources.MemoryReservation, 10)
+	}
+	if resources.MemoryHigh != 0 {
+		unified["memory.high"] = strconv.FormatInt(resources.MemoryHigh, 10)
+	}
+	if resources.MemorySwap > 0 {
+		swapMax := resources.MemorySwap - resources.Memory
+		if swapMax < 0 {
+			swapMax = 0
+		}
+		unified["memory.swap.max"] = strconv.FormatInt(swapMax, 10)
+	}
+	if resources.Memory != 0 {
+		memLimit := resources.Memory
+		r.Memory = &specs.LinuxMemory{Limit: &memLimit}
+	}
+
+	if resources.CPUShares != 0 {
+		unified["cpu.weight"] = strconv.FormatUint(convertCPUSharesToCPUWeight(uint64(resources.CPUShares)), 10)
+	}
+	if period, quota := cpuQuotaPeriod(resources); period != 0 || quota != 0 {
+		unified["cpu.max"] = convertCPUQuotaToCPUMax(period, quota)
+	}
+
+	cpu := specs.LinuxCPU{
+		Cpus: resources.CpusetCpus,
+		Mems: resources.CpusetMems,
+	}
+	if cpu != (specs.LinuxCPU{}) {
+		r.CPU = &cpu
+	}
+
+	r.Unified = unified
+	r.Pids = getPidsLimit(resources)
+	return &r, nil
+}
+
+// cpuQuotaPeriod resolves the effective CPU bandwidth period/quota pair
+// from resources, preferring NanoCPUs when set and otherwise falling back
+// to the explicit CPUQuota/CPUPeriod fields. It's shared by the v1 and v2
+// resource builders since the bandwidth controller is unchanged between
+// cgroup versions.
+func cpuQuotaPeriod(resources container.Resources) (period uint64, quota int64) {
+	if resources.NanoCPUs != 0 {
+		period = uint64(100 * time.Millisecond / time.Microsecond)
+		quota = resources.NanoCPUs * int64(period) / 1e9
+	}
+	if quota == 0 && resources.CPUQuota != 0 {
+		quota = resources.CPUQuota
+	}
+	if period == 0 && resources.CPUPeriod != 0 {
+		period = uint64(resources.CPUPeriod)
+	}
+	return period, quota
+}
+
+// convertCPUSharesToCPUWeight converts a v1 cpu.shares value (2-262144) to
+// the v2 cpu.weight range (1-10000), using the linear mapping documented in
+// the kernel's cgroup v2 control files.
+//
+// shares below the valid minimum of 2 (0, which toContainerdResourcesV2
+// treats as "unset" and never passes, or 1 from an explicit CPUShares: 1)
+// are clamped to 2 rather than subtracted directly: shares-2 underflows a
+// uint64 for shares < 2, producing a huge bogus weight instead of the
+// minimum one.
+func convertCPUSharesToCPUWeight(shares uint64) uint64 {
+	if shares < 2 {
+		shares = 2
+	}
+	return 1 + ((shares-2)*9999)/262142
+}
+
+// convertCPUQuotaToCPUMax renders a cpu bandwidth period/quota pair as a
+// cgroup v2 cpu.max value: "$MAX $PERIOD", using the literal "max" for an
+// unset (unlimited) quota.
+func convertCPUQuotaToCPUMax(period uint64, quota int64) string {
+	if period == 0 {
+		period = uint64(100 * time.Millisecond / time.Microsecond)
+	}
+	max := "max"
+	if quota > 0 {
+		max = strconv.FormatInt(quota, 10)
+	}
+	return max + " " + strconv.FormatUint(period, 10)
+}
+
+// convertBlkioWeightToIOWeight converts a v1 blkio.weight value (10-1000)
+// to the v2 io.weight range (1-10000) using the same linear mapping runc
+// applies when translating a v1-shaped spec onto a v2 host.
+func convertBlkioWeightToIOWeight(weight uint16) uint64 {
+	return 1 + (uint64(weight)-10)*9999/990
+}
+
+// blkioDeviceLimits accumulates the four v1 per-device blkio throttle
+// settings for a single device node so they can be emitted as one io.max
+// line.
+type blkioDeviceLimits struct {
+	rbps, wbps, riops, wiops uint64
+}
+
+// convertBlkioThrottleToIOMax renders resources' per-device v1 throttle
+// settings as cgroup v2 io.max lines: one "<major>:<minor> rbps=.. wbps=..
+// riops=.. wiops=.." entry per device, newline-separated, using "max" for
+// whichever of the four a device has no throttle set for.
+func convertBlkioThrottleToIOMax(resources container.Resources) (string, error) {
+	limits := map[string]*blkioDeviceLimits{}
+	get := func(path string) *blkioDeviceLimits {
+		l, ok := limits[path]
+		if !ok {
+			l = &blkioDeviceLimits{}
+			limits[path] = l
+		}
+		return l
+	}
+	for _, d := range resources.BlkioDeviceReadBps {
+		get(d.Path).rbps = d.Rate
+	}
+	for _, d := range resources.BlkioDeviceWriteBps {
+		get(d.Path).wbps = d.Rate
+	}
+	for _, d := range resources.BlkioDeviceReadIOps {
+		get(d.Path).riops = d.Rate
+	}
+	for _, d := range resources.BlkioDeviceWriteIOps {
+		get(d.Path).wiops = d.Rate
+	}
+	if len(limits) == 0 {
+		return "", nil
+	}
+
+	paths := make([]string, 0, len(limits))
+	for path := range limits {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	lines := make([]string, 0, len(paths))
+	for _, path := range paths {
+		major, minor, err := devNumbers(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "error resolving device numbers for %s", path)
+		}
+		l := limits[path]
+		lines = append(lines, fmt.Sprintf("%d:%d rbps=%s wbps=%s riops=%s wiops=%s",
+			major, minor, ioMaxValue(l.rbps), ioMaxValue(l.wbps), ioMaxValue(l.riops), ioMaxValue(l.wiops)))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// ioMaxValue renders a single io.max rate, using the literal "max" the
+// control file expects for "no limit" in place of a zero value.
+func ioMaxValue(rate uint64) string {
+	if rate == 0 {
+		return "max"
+	}
+	return strconv.FormatUint(rate, 10)
+}
+
+// devNumbers resolves the major:minor device numbers backing path, as
+// needed to key a cgroup v2 io.max line.
+func devNumbers(path string) (uint64, uint64, error) {
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return 0, 0, errors.Wrap(err, "error statting device")
+	}
+	dev := uint64(stat.Rdev)
+	return unix.Major(dev), unix.Minor(dev), nil
+}
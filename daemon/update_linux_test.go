@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"gotest.tools/v3/assert"
+)
+
+func TestConvertCPUSharesToCPUWeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		shares uint64
+		want   uint64
+	}{
+		{name: "zero", shares: 0, want: 1},
+		{name: "below minimum", shares: 1, want: 1},
+		{name: "minimum", shares: 2, want: 1},
+		{name: "default", shares: 1024, want: 39},
+		{name: "maximum", shares: 262144, want: 10000},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, convertCPUSharesToCPUWeight(tc.shares), tc.want)
+		})
+	}
+}
+
+func TestConvertBlkioWeightToIOWeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight uint16
+		want   uint64
+	}{
+		{name: "minimum", weight: 10, want: 1},
+		{name: "default", weight: 500, want: 4950},
+		{name: "maximum", weight: 1000, want: 10000},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, convertBlkioWeightToIOWeight(tc.weight), tc.want)
+		})
+	}
+}
+
+func TestToContainerdResourcesV2CPUWeight(t *testing.T) {
+	tests := []struct {
+		name       string
+		shares     int64
+		wantWeight string
+		wantUnset  bool
+	}{
+		{name: "unset", shares: 0, wantUnset: true},
+		{name: "minimum", shares: 2, wantWeight: "1"},
+		{name: "default", shares: 1024, wantWeight: "39"},
+		{name: "maximum", shares: 262144, wantWeight: "10000"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := toContainerdResourcesV2(container.Resources{CPUShares: tc.shares})
+			assert.NilError(t, err)
+			weight, ok := r.Unified["cpu.weight"]
+			if tc.wantUnset {
+				assert.Equal(t, ok, false)
+				return
+			}
+			assert.Equal(t, ok, true)
+			assert.Equal(t, weight, tc.wantWeight)
+		})
+	}
+}
+
+func TestToContainerdResourcesV2MemoryHigh(t *testing.T) {
+	tests := []struct {
+		name      string
+		memHigh   int64
+		wantValue string
+		wantUnset bool
+	}{
+		{name: "unset", memHigh: 0, wantUnset: true},
+		{name: "set", memHigh: 100 * 1024 * 1024, wantValue: "104857600"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := toContainerdResourcesV2(container.Resources{MemoryHigh: tc.memHigh})
+			assert.NilError(t, err)
+			value, ok := r.Unified["memory.high"]
+			if tc.wantUnset {
+				assert.Equal(t, ok, false)
+				return
+			}
+			assert.Equal(t, ok, true)
+			assert.Equal(t, value, tc.wantValue)
+		})
+	}
+}
+
+func TestConvertCPUQuotaToCPUMax(t *testing.T) {
+	tests := []struct {
+		name   string
+		period uint64
+		quota  int64
+		want   string
+	}{
+		{name: "unset", period: 0, quota: 0, want: "max 100000"},
+		{name: "quota and period", period: 50000, quota: 25000, want: "25000 50000"},
+		{name: "quota without explicit period", period: 0, quota: 10000, want: "10000 100000"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, convertCPUQuotaToCPUMax(tc.period, tc.quota), tc.want)
+		})
+	}
+}